@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gabrie30/ghorg/colorlog"
 	"github.com/gabrie30/ghorg/configs"
@@ -29,6 +30,11 @@ var cloneCmd = &cobra.Command{
 
 func cloneFunc(cmd *cobra.Command, argz []string) {
 
+	if cmd.Flags().Changed("config-file") {
+		runConfigFile(cmd.Flag("config-file").Value.String())
+		return
+	}
+
 	if cmd.Flags().Changed("path") {
 		absolutePath := configs.EnsureTrailingSlash((cmd.Flag("path").Value.String()))
 		os.Setenv("GHORG_ABSOLUTE_PATH_TO_CLONE_TO", absolutePath)
@@ -123,6 +129,62 @@ func cloneFunc(cmd *cobra.Command, argz []string) {
 		os.Setenv("GHORG_OUTPUT_DIR", d)
 	}
 
+	if cmd.Flags().Changed("mirror-to") {
+		os.Setenv("GHORG_MIRROR_TO", cmd.Flag("mirror-to").Value.String())
+	}
+
+	if cmd.Flags().Changed("log-format") {
+		os.Setenv("GHORG_LOG_FORMAT", cmd.Flag("log-format").Value.String())
+	}
+
+	if cmd.Flags().Changed("log-level") {
+		os.Setenv("GHORG_LOG_LEVEL", cmd.Flag("log-level").Value.String())
+	}
+
+	if cmd.Flags().Changed("metrics-addr") {
+		os.Setenv("GHORG_METRICS_ADDR", cmd.Flag("metrics-addr").Value.String())
+	}
+
+	if cmd.Flags().Changed("metrics-pushgateway") {
+		os.Setenv("GHORG_METRICS_PUSHGATEWAY", cmd.Flag("metrics-pushgateway").Value.String())
+	}
+
+	if cmd.Flags().Changed("resume") {
+		os.Setenv("GHORG_RESUME", "true")
+	}
+
+	if cmd.Flags().Changed("skip-if-newer-than") {
+		os.Setenv("GHORG_SKIP_IF_NEWER_THAN", cmd.Flag("skip-if-newer-than").Value.String())
+	}
+
+	if cmd.Flags().Changed("max-retries") {
+		os.Setenv("GHORG_MAX_RETRIES", cmd.Flag("max-retries").Value.String())
+	}
+
+	if cmd.Flags().Changed("filter") {
+		os.Setenv("GHORG_FILTER", cmd.Flag("filter").Value.String())
+	}
+
+	if cmd.Flags().Changed("depth") {
+		os.Setenv("GHORG_CLONE_DEPTH", cmd.Flag("depth").Value.String())
+	}
+
+	if cmd.Flags().Changed("filter-spec") {
+		os.Setenv("GHORG_CLONE_FILTER_SPEC", cmd.Flag("filter-spec").Value.String())
+	}
+
+	if cmd.Flags().Changed("bare") {
+		os.Setenv("GHORG_BARE", "true")
+	}
+
+	if cmd.Flags().Changed("shallow-above-mb") {
+		os.Setenv("GHORG_SHALLOW_ABOVE_MB", cmd.Flag("shallow-above-mb").Value.String())
+	}
+
+	if cmd.Flags().Changed("promote-shallow") {
+		os.Setenv("GHORG_PROMOTE_SHALLOW", "true")
+	}
+
 	if len(argz) < 1 {
 		if os.Getenv("GHORG_SCM_TYPE") == "github" && os.Getenv("GHORG_CLONE_TYPE") == "user" {
 			argz = append(argz, "")
@@ -161,10 +223,18 @@ func cloneFunc(cmd *cobra.Command, argz []string) {
 	parseParentFolder(argz)
 	args = argz
 	targetCloneSource = argz[0]
-	setupRepoClone()
+
+	// setupRepoClone returns an error instead of exiting itself so that
+	// runConfigFileJob can isolate a single scheduled job's failure from the
+	// rest of a long running --config-file daemon; the single-shot cli path
+	// below is the only caller that turns that error into a process exit.
+	if err := setupRepoClone(); err != nil {
+		colorlog.PrintError(err)
+		os.Exit(1)
+	}
 }
 
-func setupRepoClone() {
+func setupRepoClone() error {
 	var cloneTargets []scm.Repo
 	var err error
 
@@ -173,22 +243,20 @@ func setupRepoClone() {
 	} else if os.Getenv("GHORG_CLONE_TYPE") == "user" {
 		cloneTargets, err = getAllUserCloneUrls()
 	} else {
-		colorlog.PrintError("GHORG_CLONE_TYPE not set or unsupported")
-		os.Exit(1)
+		return fmt.Errorf("GHORG_CLONE_TYPE not set or unsupported")
 	}
 
 	if err != nil {
-		colorlog.PrintError("Encountered an error, aborting")
-		fmt.Println(err)
-		os.Exit(1)
+		return fmt.Errorf("encountered an error, aborting: %w", err)
 	}
 
 	if len(cloneTargets) == 0 {
 		colorlog.PrintInfo("No repos found for " + os.Getenv("GHORG_SCM_TYPE") + " " + os.Getenv("GHORG_CLONE_TYPE") + ": " + targetCloneSource + ", please verify you have sufficient permissions to clone target repos, double check spelling and try again.")
-		os.Exit(0)
+		return nil
 	}
-	git := git.NewGit()
-	CloneAllRepos(git, cloneTargets)
+	g := git.NewGit()
+	CloneAllRepos(g, cloneTargets)
+	return nil
 }
 
 func getAllOrgCloneUrls() ([]scm.Repo, error) {
@@ -204,19 +272,17 @@ func getCloneUrls(isOrg bool) ([]scm.Repo, error) {
 	PrintConfigs()
 	scmType := strings.ToLower(os.Getenv("GHORG_SCM_TYPE"))
 	if len(scmType) == 0 {
-		colorlog.PrintError("GHORG_SCM_TYPE not set")
-		os.Exit(1)
+		return nil, fmt.Errorf("GHORG_SCM_TYPE not set")
 	}
 	client, err := scm.GetClient(scmType)
 	if err != nil {
-		colorlog.PrintError(err)
-		os.Exit(1)
+		return nil, err
 	}
 
 	if isOrg {
-		return client.GetOrgRepos(targetCloneSource)
+		return client.GetOrgRepos(targetCloneSource, repoFilter())
 	}
-	return client.GetUserRepos(targetCloneSource)
+	return client.GetUserRepos(targetCloneSource, repoFilter())
 }
 
 func createDirIfNotExist() {
@@ -322,12 +388,23 @@ func printDryRun(repos []scm.Repo) {
 func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 	// resc, errc, infoc := make(chan string), make(chan error), make(chan error)
 
+	if addr := os.Getenv("GHORG_METRICS_ADDR"); addr != "" {
+		stopMetrics := startMetricsServer(addr)
+		defer stopMetrics()
+	}
+
 	if os.Getenv("GHORG_MATCH_REGEX") != "" {
 		colorlog.PrintInfo("Filtering repos down by regex that match the provided...")
 		fmt.Println("")
 		cloneTargets = filterByRegex(cloneTargets)
 	}
 
+	if os.Getenv("GHORG_FILTER") != "" {
+		colorlog.PrintInfo("Filtering repos down by --filter criteria...")
+		fmt.Println("")
+		cloneTargets = applyRepoFilter(cloneTargets, repoFilter())
+	}
+
 	// filter repos down based on ghorgignore if one exists
 	_, err := os.Stat(configs.GhorgIgnoreLocation())
 	if !os.IsNotExist(err) {
@@ -387,6 +464,18 @@ func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 
 	limit := limiter.NewConcurrencyLimiter(l)
 
+	dests := mirrorDestinations()
+
+	manifest := loadStateManifest()
+	resuming := os.Getenv("GHORG_RESUME") == "true"
+
+	maxRetries, err := strconv.Atoi(os.Getenv("GHORG_MAX_RETRIES"))
+	if err != nil {
+		maxRetries = 0
+	}
+
+	queueDepth.Set(float64(len(cloneTargets)))
+
 	var cloneCount, pulledCount int
 
 	for _, target := range cloneTargets {
@@ -395,7 +484,27 @@ func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 		branch := target.CloneBranch
 		repo := target
 
+		if resuming && !manifest.shouldProcessOnResume(repo.URL) {
+			queueDepth.Dec()
+			continue
+		}
+
+		if manifest.shouldSkip(repo.URL) {
+			colorlog.PrintSubtleInfo("Skipping " + repo.URL + ", succeeded within GHORG_SKIP_IF_NEWER_THAN window")
+			queueDepth.Dec()
+			continue
+		}
+
 		limit.Execute(func() {
+			start := time.Now()
+			action := "cloning"
+			var actionErr error
+			defer func() {
+				logRepoAction(action, repo.URL, repo.CloneBranch, int64(repo.SizeKB)*1024, start, actionErr)
+				recordActionMetrics(action, repo.URL, repo.SizeKB, start, actionErr)
+				manifest.record(repo.URL, action, actionErr)
+				queueDepth.Dec()
+			}()
 
 			path := appName
 			if repo.Path != "" && os.Getenv("GHORG_PRESERVE_DIRECTORY_STRUCTURE") == "true" {
@@ -414,8 +523,6 @@ func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 				repo.HostPath = filepath.Join(os.Getenv("GHORG_ABSOLUTE_PATH_TO_CLONE_TO"), parentFolder+"_backup", configs.GetCorrectFilePathSeparator(), path)
 			}
 
-			action := "cloning"
-
 			if repoExistsLocally(repo) {
 				if os.Getenv("GHORG_BACKUP") == "true" {
 					err := git.UpdateRemote(repo)
@@ -430,6 +537,7 @@ func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 					if err != nil {
 						e := fmt.Sprintf("Could not update remotes in Repo: %s Error: %v", repo.URL, err)
 						cloneErrors = append(cloneErrors, e)
+						actionErr = err
 						return
 					}
 				} else if os.Getenv("GHORG_NO_CLEAN") == "true" {
@@ -446,6 +554,7 @@ func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 					if err != nil {
 						e := fmt.Sprintf("Could not fetch remotes in Repo: %s Error: %v", repo.URL, err)
 						cloneErrors = append(cloneErrors, e)
+						actionErr = err
 						return
 					}
 
@@ -463,6 +572,7 @@ func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 					if err != nil {
 						e := fmt.Sprintf("Problem running git clean: %s Error: %v", repo.URL, err)
 						cloneErrors = append(cloneErrors, e)
+						actionErr = err
 						return
 					}
 
@@ -471,26 +581,31 @@ func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 					if err != nil {
 						e := fmt.Sprintf("Problem resetting %s Repo: %s Error: %v", repo.CloneBranch, repo.URL, err)
 						cloneErrors = append(cloneErrors, e)
+						actionErr = err
 						return
 					}
 
-					err = git.Pull(repo)
+					err = retryWithBackoff(maxRetries, func() error { return git.Pull(repo) })
 
 					if err != nil {
 						e := fmt.Sprintf("Problem trying to pull %v Repo: %s Error: %v", repo.CloneBranch, repo.URL, err)
 						cloneErrors = append(cloneErrors, e)
+						actionErr = err
 						return
 					}
 
 					action = "pulling"
 					pulledCount++
 
+					promoteShallowIfRequested(git, repo, manifest)
+
 					if os.Getenv("GHORG_FETCH_ALL") == "true" {
 						err = git.FetchAll(repo)
 
 						if err != nil {
 							e := fmt.Sprintf("Could not fetch remotes in Repo: %s Error: %v", repo.URL, err)
 							cloneErrors = append(cloneErrors, e)
+							actionErr = err
 							return
 						}
 					}
@@ -498,7 +613,14 @@ func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 			} else {
 				// if https clone and github/gitlab add personal access token to url
 
-				err = git.Clone(repo)
+				opts := cloneStrategy(repo)
+				if opts.Depth > 0 {
+					action = "shallow-clone"
+				} else if opts.Bare {
+					action = "bare-clone"
+				}
+
+				err = retryWithBackoff(maxRetries, func() error { return git.Clone(repo, opts) })
 
 				// Theres no way to tell if a github repo has a wiki to clone
 				if err != nil && repo.IsWiki {
@@ -510,6 +632,7 @@ func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 				if err != nil {
 					e := fmt.Sprintf("Problem trying to clone Repo: %s Error: %v", repo.URL, err)
 					cloneErrors = append(cloneErrors, e)
+					actionErr = err
 					return
 				}
 
@@ -532,6 +655,7 @@ func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 				if err != nil {
 					e := fmt.Sprintf("Problem trying to set remote on Repo: %s Error: %v", repo.URL, err)
 					cloneErrors = append(cloneErrors, e)
+					actionErr = err
 					return
 				}
 
@@ -541,18 +665,27 @@ func CloneAllRepos(git git.Gitter, cloneTargets []scm.Repo) {
 					if err != nil {
 						e := fmt.Sprintf("Could not fetch remotes in Repo: %s Error: %v", repo.URL, err)
 						cloneErrors = append(cloneErrors, e)
+						actionErr = err
 						return
 					}
 				}
 			}
 
 			colorlog.PrintSuccess("Success " + action + " repo: " + repo.URL + " -> branch: " + branch)
+
+			if len(dests) > 0 {
+				mirrorRepo(git, repo, dests)
+			}
 		})
 
 	}
 
 	limit.Wait()
 
+	if pgw := os.Getenv("GHORG_METRICS_PUSHGATEWAY"); pgw != "" {
+		pushMetrics(pgw, targetCloneSource)
+	}
+
 	printRemainingMessages()
 
 	colorlog.PrintSuccess(fmt.Sprintf("New repos cloned: %v, existing repos pulled: %v", cloneCount, pulledCount))
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/gabrie30/ghorg/colorlog"
+	"github.com/gabrie30/ghorg/git"
+	"github.com/gabrie30/ghorg/scm"
+)
+
+func init() {
+	cloneCmd.Flags().Int("depth", 0, "GHORG_CLONE_DEPTH - shallow clone to this depth, 0 disables shallow cloning")
+	cloneCmd.Flags().String("filter-spec", "", "GHORG_CLONE_FILTER_SPEC - partial clone filter spec e.g. blob:none or tree:0, passed to git clone --filter")
+	cloneCmd.Flags().Bool("bare", false, "GHORG_BARE - clone as a bare repo, defaults to true when --backup is set")
+	cloneCmd.Flags().Int("shallow-above-mb", 0, "GHORG_SHALLOW_ABOVE_MB - shallow clone any repo whose reported size exceeds this many megabytes, 0 disables the size heuristic")
+	cloneCmd.Flags().Bool("promote-shallow", false, "GHORG_PROMOTE_SHALLOW - run git fetch --unshallow on repos the state manifest recorded as shallow")
+}
+
+// cloneStrategy is the git.CloneOpts chosen for a single repo, accounting
+// for global flags and the --shallow-above-mb size heuristic.
+func cloneStrategy(repo scm.Repo) git.CloneOpts {
+	opts := git.CloneOpts{
+		Depth:      0,
+		FilterSpec: os.Getenv("GHORG_CLONE_FILTER_SPEC"),
+		Bare:       os.Getenv("GHORG_BACKUP") == "true",
+	}
+
+	if os.Getenv("GHORG_BARE") == "true" {
+		opts.Bare = true
+	}
+
+	if depth, err := strconv.Atoi(os.Getenv("GHORG_CLONE_DEPTH")); err == nil && depth > 0 {
+		opts.Depth = depth
+	}
+
+	if thresholdMB, err := strconv.Atoi(os.Getenv("GHORG_SHALLOW_ABOVE_MB")); err == nil && thresholdMB > 0 {
+		if repo.SizeKB > thresholdMB*1024 {
+			opts.Depth = 1
+		}
+	}
+
+	return opts
+}
+
+// promoteShallowIfRequested runs git fetch --unshallow on repo when
+// --promote-shallow is set and the manifest recorded it as a shallow clone.
+func promoteShallowIfRequested(g git.Gitter, repo scm.Repo, manifest *stateManifest) {
+	if os.Getenv("GHORG_PROMOTE_SHALLOW") != "true" {
+		return
+	}
+
+	manifest.mu.Lock()
+	state, ok := manifest.Repos[repo.URL]
+	manifest.mu.Unlock()
+
+	if !ok || state.LastAction != "shallow-clone" {
+		return
+	}
+
+	if err := g.Unshallow(repo); err != nil {
+		colorlog.PrintError("Could not promote shallow clone to full history for " + repo.URL + ": " + err.Error())
+		return
+	}
+
+	colorlog.PrintSuccess("Promoted " + repo.URL + " to a full clone")
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gabrie30/ghorg/colorlog"
+	"github.com/gabrie30/ghorg/configs"
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	cloneCmd.Flags().String("config-file", "", "path to a YAML file describing one or more clone jobs to run in a single invocation")
+}
+
+// ConfigFileJob is a single clone job as declared under the top level
+// `jobs:` key of a --config-file YAML document.
+type ConfigFileJob struct {
+	Name       string `yaml:"name"`
+	SCM        string `yaml:"scm"`
+	CloneType  string `yaml:"clone_type"`
+	Target     string `yaml:"target"`
+	BaseURL    string `yaml:"base_url"`
+	TokenEnv   string `yaml:"token_env"`
+	Protocol   string `yaml:"protocol"`
+	OutputDir  string `yaml:"output_dir"`
+	MatchRegex string `yaml:"match_regex"`
+	Filter     string `yaml:"filter"`
+	Schedule   string `yaml:"schedule"`
+}
+
+// ConfigFile is the top level document accepted by --config-file.
+type ConfigFile struct {
+	Concurrency int             `yaml:"concurrency"`
+	DryRun      bool            `yaml:"dry_run"`
+	Jobs        []ConfigFileJob `yaml:"jobs"`
+}
+
+// loadConfigFile reads and parses a --config-file document. This is kept
+// local to cmd, rather than added to the configs package, since ConfigFile
+// is a cmd-local type and configs can't import cmd.
+func loadConfigFile(path string) (*ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cf ConfigFile
+	if err := yaml.Unmarshal(data, &cf); err != nil {
+		return nil, err
+	}
+
+	return &cf, nil
+}
+
+// runConfigFile loads the jobs declared in path, runs every job without a
+// schedule once, and then, if any job declares a schedule, starts a long
+// running cron daemon that runs each scheduled job on its own cadence.
+func runConfigFile(path string) {
+	cf, err := loadConfigFile(path)
+	if err != nil {
+		colorlog.PrintError(fmt.Sprintf("Could not load config file %s: %v", path, err))
+		os.Exit(1)
+	}
+
+	var scheduled, immediate []ConfigFileJob
+	for _, job := range cf.Jobs {
+		if job.Schedule != "" {
+			scheduled = append(scheduled, job)
+		} else {
+			immediate = append(immediate, job)
+		}
+	}
+
+	for _, job := range immediate {
+		runConfigFileJob(cf, job)
+	}
+
+	if len(scheduled) == 0 {
+		return
+	}
+
+	colorlog.PrintInfo(fmt.Sprintf("Starting cron daemon for %v scheduled job(s), ctrl+c to exit", len(scheduled)))
+
+	c := cron.New()
+	for _, job := range scheduled {
+		job := job
+		if _, err := c.AddFunc(job.Schedule, func() { runConfigFileJob(cf, job) }); err != nil {
+			colorlog.PrintError(fmt.Sprintf("Invalid schedule %q for job %s: %v", job.Schedule, job.Name, err))
+			os.Exit(1)
+		}
+	}
+
+	c.Run()
+}
+
+// runConfigFileJob applies a single job's settings as env vars, mirroring the
+// precedence rules used by the cli flags, then runs the normal clone flow.
+// Every job-scoped env var is set unconditionally (not just when non-empty)
+// since all scheduled jobs share this one process: without that, a job that
+// omits e.g. base_url or filter would silently inherit whatever the
+// previous job in the daemon left behind instead of falling back to its own
+// default (empty) value.
+func runConfigFileJob(cf *ConfigFile, job ConfigFileJob) {
+	colorlog.PrintInfo("Running job: " + job.Name)
+
+	os.Setenv("GHORG_SCM_TYPE", job.SCM)
+	os.Setenv("GHORG_CLONE_TYPE", job.CloneType)
+	os.Setenv("GHORG_SCM_BASE_URL", job.BaseURL)
+	os.Setenv("GHORG_CLONE_PROTOCOL", job.Protocol)
+	os.Setenv("GHORG_OUTPUT_DIR", job.OutputDir)
+	os.Setenv("GHORG_MATCH_REGEX", job.MatchRegex)
+	os.Setenv("GHORG_FILTER", job.Filter)
+
+	if job.TokenEnv != "" {
+		if token := os.Getenv(job.TokenEnv); token != "" {
+			configs.SetTokenForScmType(job.SCM, token)
+		}
+	}
+	if cf.Concurrency > 0 {
+		os.Setenv("GHORG_CONCURRENCY", fmt.Sprint(cf.Concurrency))
+	}
+	if cf.DryRun {
+		os.Setenv("GHORG_DRY_RUN", "true")
+	}
+
+	parseParentFolder([]string{job.Target})
+	args = []string{job.Target}
+	targetCloneSource = job.Target
+
+	// Unlike the single-shot cli path, a job's fatal error must not exit the
+	// process: one mistyped clone_type or a filter that matches zero repos
+	// today would otherwise take down every other scheduled job along with
+	// the cron daemon itself.
+	if err := setupRepoClone(); err != nil {
+		colorlog.PrintError(fmt.Sprintf("Job %s failed: %v", job.Name, err))
+	}
+}
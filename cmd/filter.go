@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabrie30/ghorg/colorlog"
+	"github.com/gabrie30/ghorg/configs"
+	"github.com/gabrie30/ghorg/scm"
+)
+
+func init() {
+	cloneCmd.Flags().String("filter", "", "GHORG_FILTER - inline key=value pairs (comma separated) or a path to a YAML file describing a scm.Filter")
+}
+
+// repoFilter returns the scm.Filter built from --filter, or the zero value
+// if the flag was not set. SCM adapters may pre-filter via their API with
+// this; CloneAllRepos still applies it in-memory as a fallback/final pass.
+func repoFilter() scm.Filter {
+	raw := os.Getenv("GHORG_FILTER")
+	if raw == "" {
+		return scm.Filter{}
+	}
+
+	if _, err := os.Stat(raw); err == nil {
+		f, err := configs.LoadFilterFile(raw)
+		if err != nil {
+			colorlog.PrintError("Could not load filter file " + raw + ": " + err.Error())
+			os.Exit(1)
+		}
+		return f
+	}
+
+	return parseInlineFilter(raw)
+}
+
+func parseInlineFilter(raw string) scm.Filter {
+	f := scm.Filter{}
+
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			colorlog.PrintError("Invalid --filter entry, expected key=value: " + pair)
+			os.Exit(1)
+		}
+
+		key, value := kv[0], kv[1]
+		switch key {
+		case "visibility":
+			f.Visibility = value
+		case "languages":
+			f.Languages = strings.Split(value, "|")
+		case "min_stars":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				colorlog.PrintError("Invalid min_stars in --filter: " + value)
+				os.Exit(1)
+			}
+			f.MinStars = n
+		case "updated_within":
+			d, err := parseDayDuration(value)
+			if err != nil {
+				colorlog.PrintError("Invalid updated_within in --filter: " + value)
+				os.Exit(1)
+			}
+			f.UpdatedWithin = d
+		case "max_size_kb":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				colorlog.PrintError("Invalid max_size_kb in --filter: " + value)
+				os.Exit(1)
+			}
+			f.MaxSizeKB = n
+		case "include":
+			f.IncludeGlobs = append(f.IncludeGlobs, strings.Split(value, "|")...)
+		case "exclude":
+			f.ExcludeGlobs = append(f.ExcludeGlobs, strings.Split(value, "|")...)
+		default:
+			colorlog.PrintError("Unknown --filter key: " + key)
+			os.Exit(1)
+		}
+	}
+
+	return f
+}
+
+// parseDayDuration supports the "30d" style durations the filter DSL uses,
+// since time.ParseDuration has no day unit.
+func parseDayDuration(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// applyRepoFilter is the in-memory fallback filter pass, used for any
+// criteria an SCM adapter could not pre-filter via its own API.
+func applyRepoFilter(repos []scm.Repo, f scm.Filter) []scm.Repo {
+	if f.IsEmpty() {
+		return repos
+	}
+
+	filtered := []scm.Repo{}
+	for _, r := range repos {
+		if f.Visibility != "" && r.Visibility != f.Visibility {
+			continue
+		}
+
+		if len(f.Languages) > 0 && !containsFold(f.Languages, r.Language) {
+			continue
+		}
+
+		if f.MinStars > 0 && r.Stars < f.MinStars {
+			continue
+		}
+
+		if f.UpdatedWithin > 0 && time.Since(r.LastPushedAt) > f.UpdatedWithin {
+			continue
+		}
+
+		if f.MaxSizeKB > 0 && r.SizeKB > f.MaxSizeKB {
+			continue
+		}
+
+		if len(f.IncludeGlobs) > 0 && !matchesAnyGlob(f.IncludeGlobs, r.Path) {
+			continue
+		}
+
+		if matchesAnyGlob(f.ExcludeGlobs, r.Path) {
+			continue
+		}
+
+		filtered = append(filtered, r)
+	}
+
+	return filtered
+}
+
+func containsFold(haystack []string, needle string) bool {
+	for _, h := range haystack {
+		if strings.EqualFold(h, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyGlob(globs []string, path string) bool {
+	for _, g := range globs {
+		if ok, _ := filepath.Match(g, path); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gabrie30/ghorg/scm"
+)
+
+func TestParseInlineFilter(t *testing.T) {
+	f := parseInlineFilter("visibility=private,languages=Go|Ruby,min_stars=10,updated_within=30d,max_size_kb=500,include=api-*,exclude=*-archive")
+
+	if f.Visibility != "private" {
+		t.Errorf("expected visibility private, got %q", f.Visibility)
+	}
+	if len(f.Languages) != 2 || f.Languages[0] != "Go" || f.Languages[1] != "Ruby" {
+		t.Errorf("unexpected languages: %v", f.Languages)
+	}
+	if f.MinStars != 10 {
+		t.Errorf("expected min stars 10, got %d", f.MinStars)
+	}
+	if f.UpdatedWithin != 30*24*time.Hour {
+		t.Errorf("expected updated_within 30d, got %v", f.UpdatedWithin)
+	}
+	if f.MaxSizeKB != 500 {
+		t.Errorf("expected max size 500kb, got %d", f.MaxSizeKB)
+	}
+	if len(f.IncludeGlobs) != 1 || f.IncludeGlobs[0] != "api-*" {
+		t.Errorf("unexpected include globs: %v", f.IncludeGlobs)
+	}
+	if len(f.ExcludeGlobs) != 1 || f.ExcludeGlobs[0] != "*-archive" {
+		t.Errorf("unexpected exclude globs: %v", f.ExcludeGlobs)
+	}
+}
+
+func TestParseDayDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"1d", 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"not-a-duration", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDayDuration(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDayDuration(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseDayDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestApplyRepoFilter(t *testing.T) {
+	repos := []scm.Repo{
+		{URL: "https://example.com/a.git", Visibility: "public", Language: "Go", Stars: 20, SizeKB: 100, Path: "team/a", LastPushedAt: time.Now()},
+		{URL: "https://example.com/b.git", Visibility: "private", Language: "Ruby", Stars: 2, SizeKB: 900, Path: "team/b-archive", LastPushedAt: time.Now().Add(-60 * 24 * time.Hour)},
+	}
+
+	f := scm.Filter{
+		MinStars:     5,
+		MaxSizeKB:    500,
+		ExcludeGlobs: []string{"*-archive"},
+	}
+
+	got := applyRepoFilter(repos, f)
+
+	if len(got) != 1 || got[0].URL != repos[0].URL {
+		t.Fatalf("expected only repo a to survive the filter, got %+v", got)
+	}
+}
+
+func TestApplyRepoFilterEmptyIsNoop(t *testing.T) {
+	repos := []scm.Repo{{URL: "https://example.com/a.git"}}
+
+	got := applyRepoFilter(repos, scm.Filter{})
+
+	if len(got) != 1 {
+		t.Fatalf("expected empty filter to pass all repos through, got %+v", got)
+	}
+}
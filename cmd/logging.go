@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func init() {
+	cloneCmd.Flags().String("log-format", "", "GHORG_LOG_FORMAT - console|json, defaults to console")
+	cloneCmd.Flags().String("log-level", "", "GHORG_LOG_LEVEL - debug|info|warn|error, defaults to info")
+}
+
+// rootLogger is the zerolog logger all stage sub-loggers are created from. It
+// is built lazily, the first time a sub-logger is requested, so it reads
+// GHORG_LOG_FORMAT/GHORG_LOG_LEVEL after cloneFunc has set them from flags
+// rather than at package init time.
+var (
+	rootLogger     zerolog.Logger
+	rootLoggerOnce sync.Once
+)
+
+func getRootLogger() zerolog.Logger {
+	rootLoggerOnce.Do(func() { rootLogger = newRootLogger() })
+	return rootLogger
+}
+
+func newRootLogger() zerolog.Logger {
+	level, err := zerolog.ParseLevel(os.Getenv("GHORG_LOG_LEVEL"))
+	if err != nil || level == zerolog.NoLevel {
+		level = zerolog.InfoLevel
+	}
+
+	var w = os.Stdout
+	if os.Getenv("GHORG_LOG_FORMAT") == "json" {
+		return zerolog.New(w).Level(level).With().Timestamp().Logger()
+	}
+
+	return zerolog.New(zerolog.ConsoleWriter{Out: w, TimeFormat: time.RFC3339}).Level(level).With().Timestamp().Logger()
+}
+
+// createSubLogger returns a logger scoped to a single clone/pull/fetch stage
+// and repo URL, so every structured event can be traced back to both.
+func createSubLogger(stage, url string) zerolog.Logger {
+	return getRootLogger().With().Str("stage", stage).Str("url", url).Logger()
+}
+
+// logRepoAction emits one structured event for a single repo action, in
+// addition to the human readable colorlog output used for the live summary.
+func logRepoAction(stage, url, branch string, bytes int64, start time.Time, err error) {
+	sub := createSubLogger(stage, url)
+	evt := sub.Info()
+	if err != nil {
+		evt = sub.Error().Err(err)
+	}
+	evt.Dur("duration", time.Since(start)).Str("branch", branch).Int64("bytes", bytes).Msg(stage)
+}
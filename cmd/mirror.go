@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gabrie30/ghorg/colorlog"
+	"github.com/gabrie30/ghorg/git"
+	"github.com/gabrie30/ghorg/scm"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	cloneCmd.Flags().String("mirror-to", "", "GHORG_MIRROR_TO - comma separated list of destinations (type=url,type=url) to mirror each repo to after cloning/pulling e.g. gitea=https://gitea.example.com")
+}
+
+// MirrorDestination describes a secondary SCM or bare-repo directory tree that
+// cloned/pulled repos should be pushed to once ghorg has finished with them.
+// This is kept local to cmd, rather than added to the configs package, since
+// configs can't import cmd back to declare a []MirrorDestination field.
+type MirrorDestination struct {
+	Type       string `yaml:"type"` // gitea, gitlab, gogs, or local
+	URL        string `yaml:"url"`
+	Token      string `yaml:"token"`
+	Visibility string `yaml:"visibility"`
+	CreateOrg  bool   `yaml:"create_org"`
+	Path       string `yaml:"path"`
+}
+
+// mirrorTargetsFromConf reads the `mirror_targets:` list out of the conf.yaml
+// pointed to by GHORG_CONF, if one is configured.
+func mirrorTargetsFromConf() []MirrorDestination {
+	path := os.Getenv("GHORG_CONF")
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var doc struct {
+		MirrorTargets []MirrorDestination `yaml:"mirror_targets"`
+	}
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		colorlog.PrintError("Could not parse mirror_targets from " + path + ": " + err.Error())
+		return nil
+	}
+
+	return doc.MirrorTargets
+}
+
+// mirrorDestinations returns the destinations configured via --mirror-to/GHORG_MIRROR_TO
+// combined with any declared in conf.yaml.
+func mirrorDestinations() []MirrorDestination {
+	dests := mirrorTargetsFromConf()
+
+	raw := os.Getenv("GHORG_MIRROR_TO")
+	if raw == "" {
+		return dests
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			colorlog.PrintError("Invalid --mirror-to entry, expected type=url: " + entry)
+			os.Exit(1)
+		}
+
+		dests = append(dests, MirrorDestination{
+			Type:      strings.ToLower(parts[0]),
+			URL:       parts[1],
+			CreateOrg: true,
+		})
+	}
+
+	return dests
+}
+
+// mirrorRepo pushes repo to every configured destination, auto-creating the
+// remote org/user/repo when the destination supports it and CreateOrg is set.
+func mirrorRepo(g git.Gitter, repo scm.Repo, dests []MirrorDestination) {
+	for _, dest := range dests {
+		client, err := scm.GetMirrorClient(dest.Type, dest.URL, dest.Token)
+		if err != nil {
+			e := fmt.Sprintf("Could not create mirror client for %s Repo: %s Error: %v", dest.Type, repo.URL, err)
+			cloneErrors = append(cloneErrors, e)
+			continue
+		}
+
+		if dest.CreateOrg {
+			if err := client.EnsureRepoExists(repo, dest.Visibility); err != nil {
+				e := fmt.Sprintf("Could not create mirror destination for Repo: %s Error: %v", repo.URL, err)
+				cloneErrors = append(cloneErrors, e)
+				continue
+			}
+		}
+
+		if err := g.Mirror(repo, client.RemoteURL(repo, dest)); err != nil {
+			e := fmt.Sprintf("Could not mirror Repo: %s to %s Error: %v", repo.URL, dest.URL, err)
+			cloneErrors = append(cloneErrors, e)
+			continue
+		}
+
+		colorlog.PrintSuccess("Mirrored " + repo.URL + " -> " + dest.URL)
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gabrie30/ghorg/colorlog"
+)
+
+func init() {
+	cloneCmd.Flags().Bool("resume", false, "GHORG_RESUME - only re-process repos the state manifest marked as failed or missing on a previous run")
+	cloneCmd.Flags().String("skip-if-newer-than", "", "GHORG_SKIP_IF_NEWER_THAN - skip repos that succeeded within this duration e.g. 12h")
+	cloneCmd.Flags().Int("max-retries", 0, "GHORG_MAX_RETRIES - number of times to retry a repo action with exponential backoff before giving up")
+}
+
+// RepoState is the manifest entry persisted per repo so a large org clone can
+// be resumed instead of reprocessing everything from scratch.
+type RepoState struct {
+	LastCommit   string    `json:"last_commit"`
+	LastAction   string    `json:"last_action"`
+	LastAttempt  time.Time `json:"last_attempt"`
+	LastError    string    `json:"last_error,omitempty"`
+	AttemptCount int       `json:"attempt_count"`
+}
+
+// stateManifest is the on-disk .ghorg/state.json file, keyed by repo URL.
+type stateManifest struct {
+	mu    sync.Mutex
+	path  string
+	Repos map[string]RepoState `json:"repos"`
+}
+
+func manifestPath() string {
+	return filepath.Join(os.Getenv("GHORG_ABSOLUTE_PATH_TO_CLONE_TO"), parentFolder, ".ghorg", "state.json")
+}
+
+func loadStateManifest() *stateManifest {
+	m := &stateManifest{path: manifestPath(), Repos: map[string]RepoState{}}
+
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		return m
+	}
+
+	if err := json.Unmarshal(data, m); err != nil {
+		colorlog.PrintError("Could not parse " + m.path + ", starting with a fresh manifest: " + err.Error())
+		m.Repos = map[string]RepoState{}
+	}
+
+	return m
+}
+
+func (m *stateManifest) save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(m.path, data, 0600)
+}
+
+func (m *stateManifest) record(url, action string, attemptErr error) {
+	m.mu.Lock()
+	state := m.Repos[url]
+	state.LastAction = action
+	state.LastAttempt = time.Now()
+	if attemptErr != nil {
+		state.LastError = attemptErr.Error()
+		state.AttemptCount++
+	} else {
+		state.LastError = ""
+		state.AttemptCount = 0
+	}
+	m.Repos[url] = state
+	m.mu.Unlock()
+
+	if err := m.save(); err != nil {
+		colorlog.PrintError("Could not write state manifest: " + err.Error())
+	}
+}
+
+// shouldSkip reports whether url last succeeded within the configured
+// --skip-if-newer-than window, so recently synced repos can be skipped on any
+// run, with or without --resume.
+func (m *stateManifest) shouldSkip(url string) bool {
+	window := os.Getenv("GHORG_SKIP_IF_NEWER_THAN")
+	if window == "" {
+		return false
+	}
+
+	d, err := time.ParseDuration(window)
+	if err != nil {
+		return false
+	}
+
+	m.mu.Lock()
+	state, ok := m.Repos[url]
+	m.mu.Unlock()
+
+	if !ok || state.LastError != "" {
+		return false
+	}
+
+	return time.Since(state.LastAttempt) < d
+}
+
+// shouldProcessOnResume reports whether url needs reprocessing when --resume
+// is set: only repos missing from the manifest or previously failed qualify.
+func (m *stateManifest) shouldProcessOnResume(url string) bool {
+	m.mu.Lock()
+	state, ok := m.Repos[url]
+	m.mu.Unlock()
+
+	return !ok || state.LastError != ""
+}
+
+// retryWithBackoff retries fn up to maxRetries times with exponential
+// backoff, returning the last error encountered.
+func retryWithBackoff(maxRetries int, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+
+		if attempt == maxRetries {
+			break
+		}
+
+		time.Sleep(time.Duration(1<<uint(attempt)) * time.Second)
+	}
+
+	return err
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryWithBackoffSucceedsEventually(t *testing.T) {
+	attempts := 0
+	err := retryWithBackoff(3, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryWithBackoffReturnsLastError(t *testing.T) {
+	wantErr := errors.New("still failing")
+	attempts := 0
+
+	err := retryWithBackoff(2, func() error {
+		attempts++
+		return wantErr
+	})
+
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 1 initial attempt + 2 retries = 3, got %d", attempts)
+	}
+}
+
+func TestStateManifestShouldSkip(t *testing.T) {
+	t.Setenv("GHORG_SKIP_IF_NEWER_THAN", "1h")
+
+	m := &stateManifest{Repos: map[string]RepoState{
+		"https://example.com/fresh.git": {LastAttempt: time.Now()},
+		"https://example.com/stale.git": {LastAttempt: time.Now().Add(-2 * time.Hour)},
+		"https://example.com/failed.git": {LastAttempt: time.Now(), LastError: "boom"},
+	}}
+
+	if !m.shouldSkip("https://example.com/fresh.git") {
+		t.Error("expected a recently succeeded repo to be skipped")
+	}
+	if m.shouldSkip("https://example.com/stale.git") {
+		t.Error("expected a stale repo to not be skipped")
+	}
+	if m.shouldSkip("https://example.com/failed.git") {
+		t.Error("expected a previously failed repo to not be skipped")
+	}
+	if m.shouldSkip("https://example.com/unknown.git") {
+		t.Error("expected an unknown repo to not be skipped")
+	}
+}
+
+func TestStateManifestShouldProcessOnResume(t *testing.T) {
+	m := &stateManifest{Repos: map[string]RepoState{
+		"https://example.com/ok.git":     {},
+		"https://example.com/failed.git": {LastError: "boom"},
+	}}
+
+	if m.shouldProcessOnResume("https://example.com/ok.git") {
+		t.Error("expected a previously successful repo to be skipped on resume")
+	}
+	if !m.shouldProcessOnResume("https://example.com/failed.git") {
+		t.Error("expected a previously failed repo to be reprocessed on resume")
+	}
+	if !m.shouldProcessOnResume("https://example.com/missing.git") {
+		t.Error("expected a repo missing from the manifest to be reprocessed on resume")
+	}
+}